@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKSProvider is a KeyProvider that fetches signing keys from a remote
+// JWKS endpoint (as published by an identity server), caches them by
+// kid, honors the response's Cache-Control max-age, and refreshes when
+// an unknown kid is seen. minRefreshInterval bounds how often it will hit
+// the endpoint even when unknown kids keep arriving, to avoid a refresh
+// stampede.
+type JWKSProvider struct {
+	url                string
+	httpClient         *http.Client
+	minRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	maxAge      time.Duration
+	lastRefresh time.Time
+}
+
+// NewJWKSProvider creates a JWKSProvider fetching keys from url.
+func NewJWKSProvider(url string, minRefreshInterval time.Duration) *JWKSProvider {
+	if minRefreshInterval <= 0 {
+		minRefreshInterval = time.Minute
+	}
+	return &JWKSProvider{
+		url:                url,
+		httpClient:         http.DefaultClient,
+		minRefreshInterval: minRefreshInterval,
+		keys:               make(map[string]interface{}),
+	}
+}
+
+func (p *JWKSProvider) Key(kid, _ string) (interface{}, error) {
+	p.mu.RLock()
+	key, ok := p.keys[kid]
+	expired := time.Since(p.fetchedAt) > p.maxAge
+	p.mu.RUnlock()
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	if err := p.refresh(); err != nil {
+		if ok {
+			// Serve the stale key rather than fail every request just
+			// because the identity server is briefly unreachable.
+			return key, nil
+		}
+		return nil, err
+	}
+
+	p.mu.RLock()
+	key, ok = p.keys[kid]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("auth: unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+func (p *JWKSProvider) refresh() error {
+	p.mu.Lock()
+	if time.Since(p.lastRefresh) < p.minRefreshInterval {
+		p.mu.Unlock()
+		return nil
+	}
+	p.lastRefresh = time.Now()
+	p.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, p.url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth: JWKS endpoint returned %s", resp.Status)
+	}
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("auth: decoding JWKS response: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(doc.Keys))
+	for _, k := range doc.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.fetchedAt = time.Now()
+	p.maxAge = cacheMaxAge(resp.Header.Get("Cache-Control"))
+	p.mu.Unlock()
+
+	return nil
+}
+
+func cacheMaxAge(cacheControl string) time.Duration {
+	const defaultMaxAge = 15 * time.Minute
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, "max-age=") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultMaxAge
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64URLBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		e := 0
+		for _, b := range eBytes {
+			e = e<<8 | int(b)
+		}
+		return &rsa.PublicKey{N: n, E: e}, nil
+	case "EC":
+		x, err := base64URLBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64URLBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		curve, err := ecCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("auth: unsupported JWK key type %q", k.Kty)
+	}
+}
+
+func base64URLBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func ecCurve(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("auth: unsupported EC curve %q", name)
+	}
+}
@@ -5,22 +5,75 @@ import (
 	"go.uber.org/zap"
 )
 
+// JWTValidator validates JWTs against a KeyProvider. It restricts which
+// signing algorithms are accepted (rejecting "none" and any algorithm
+// that wasn't explicitly allowed, to avoid alg-confusion attacks) and,
+// when configured, checks the "iss" and "aud" claims; "exp"/"nbf" are
+// always checked by the underlying JWT library.
 type JWTValidator struct {
-	secret []byte
+	keys   KeyProvider
 	logger *zap.Logger
+
+	allowedAlgs []string
+	issuer      string
+	audience    string
 }
 
-func NewJWTValidator(secret string, logger *zap.Logger) *JWTValidator {
-	return &JWTValidator{
-		secret: []byte(secret),
-		logger: logger,
+// Option configures a JWTValidator.
+type Option func(*JWTValidator)
+
+// WithAllowedAlgorithms restricts Validate to tokens signed with one of
+// algs. Defaults to []string{"HS256"} if never set.
+func WithAllowedAlgorithms(algs ...string) Option {
+	return func(v *JWTValidator) { v.allowedAlgs = algs }
+}
+
+// WithIssuer requires the "iss" claim to equal issuer.
+func WithIssuer(issuer string) Option {
+	return func(v *JWTValidator) { v.issuer = issuer }
+}
+
+// WithAudience requires the "aud" claim to contain audience.
+func WithAudience(audience string) Option {
+	return func(v *JWTValidator) { v.audience = audience }
+}
+
+// NewJWTValidatorWithKeyProvider creates a JWTValidator backed by an
+// arbitrary KeyProvider (e.g. a static RSA/ECDSA public key, or a
+// JWKSProvider), for services that can't share a single HMAC secret.
+func NewJWTValidatorWithKeyProvider(keys KeyProvider, logger *zap.Logger, opts ...Option) *JWTValidator {
+	v := &JWTValidator{
+		keys:        keys,
+		logger:      logger,
+		allowedAlgs: []string{"HS256"},
+	}
+	for _, opt := range opts {
+		opt(v)
 	}
+	return v
+}
+
+// NewJWTValidator creates a JWTValidator backed by a single shared HMAC
+// secret. Kept as a thin wrapper for backward compatibility; prefer
+// NewJWTValidatorWithKeyProvider with an RSA/ECDSA key or a JWKSProvider
+// for services that need asymmetric signing.
+func NewJWTValidator(secret string, logger *zap.Logger) *JWTValidator {
+	return NewJWTValidatorWithKeyProvider(NewStaticKeyProvider([]byte(secret)), logger, WithAllowedAlgorithms("HS256"))
 }
 
 func (v *JWTValidator) Validate(tokenString string) (*jwt.MapClaims, error) {
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods(v.allowedAlgs)}
+	if v.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(v.audience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return v.secret, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(kid, token.Method.Alg())
+	}, parserOpts...)
 	if err != nil {
 		if v.logger != nil {
 			v.logger.Warn("JWT validation failed", zap.Error(err))
@@ -30,7 +83,7 @@ func (v *JWTValidator) Validate(tokenString string) (*jwt.MapClaims, error) {
 
 	claims, ok := token.Claims.(*jwt.MapClaims)
 	if !ok || !token.Valid {
-		return nil, jwt.ErrSignatureInvalid
+		return nil, jwt.ErrTokenSignatureInvalid
 	}
 
 	return claims, nil
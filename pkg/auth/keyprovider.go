@@ -0,0 +1,30 @@
+package auth
+
+import "fmt"
+
+// KeyProvider resolves the verification key for a JWT, selected by the
+// token header's key ID and algorithm.
+type KeyProvider interface {
+	// Key returns the verification key for kid signed with alg (e.g. a
+	// []byte HMAC secret, an *rsa.PublicKey, or an *ecdsa.PublicKey).
+	Key(kid, alg string) (interface{}, error)
+}
+
+// StaticKeyProvider is a KeyProvider for a single fixed key, used when
+// the exchange shares one signing key (HMAC secret, or an RSA/ECDSA
+// public key) rather than rotating through a JWKS endpoint.
+type StaticKeyProvider struct {
+	key interface{}
+}
+
+// NewStaticKeyProvider wraps a fixed verification key as a KeyProvider.
+func NewStaticKeyProvider(key interface{}) *StaticKeyProvider {
+	return &StaticKeyProvider{key: key}
+}
+
+func (p *StaticKeyProvider) Key(_, _ string) (interface{}, error) {
+	if p.key == nil {
+		return nil, fmt.Errorf("auth: no key configured")
+	}
+	return p.key, nil
+}
@@ -0,0 +1,55 @@
+package authz
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Require checks that the caller authenticated in ctx (by
+// interceptors.AuthInterceptor) holds permission, returning a
+// codes.PermissionDenied error if not. Use this in handlers that need an
+// in-method check beyond what AuthorizationInterceptor enforces for the
+// whole method.
+func Require(ctx context.Context, permission string) error {
+	if contains(permissionsFromContext(ctx), permission) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "missing required permission: %s", permission)
+}
+
+// Check reports whether ctx's caller satisfies req.
+func Check(ctx context.Context, req Requirement) bool {
+	roles := rolesFromContext(ctx)
+	permissions := permissionsFromContext(ctx)
+
+	if req.RequireAll {
+		for _, role := range req.Roles {
+			if !contains(roles, role) {
+				return false
+			}
+		}
+		for _, permission := range req.Permissions {
+			if !contains(permissions, permission) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(req.Roles) == 0 && len(req.Permissions) == 0 {
+		return true
+	}
+	for _, role := range req.Roles {
+		if contains(roles, role) {
+			return true
+		}
+	}
+	for _, permission := range req.Permissions {
+		if contains(permissions, permission) {
+			return true
+		}
+	}
+	return false
+}
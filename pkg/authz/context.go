@@ -0,0 +1,29 @@
+package authz
+
+import "context"
+
+// Context keys set by interceptors.AuthInterceptor once a JWT is
+// validated, and read by Require/Check and AuthorizationInterceptor.
+const (
+	RolesContextKey       = "roles"
+	PermissionsContextKey = "permissions"
+)
+
+func rolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(RolesContextKey).([]string)
+	return roles
+}
+
+func permissionsFromContext(ctx context.Context) []string {
+	permissions, _ := ctx.Value(PermissionsContextKey).([]string)
+	return permissions
+}
+
+func contains(list []string, target string) bool {
+	for _, v := range list {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package authz
+
+import "strings"
+
+// Requirement describes what a caller must hold to pass authorization for
+// a method.
+type Requirement struct {
+	Roles       []string
+	Permissions []string
+	// RequireAll, if true, means the caller must hold ALL listed
+	// roles/permissions rather than ANY of them.
+	RequireAll bool
+}
+
+// Policy maps a full gRPC method name, or a wildcard such as
+// "/exchange.OrderService/*", to the Requirement for calling it. Methods
+// with no matching rule are allowed through unchecked.
+type Policy struct {
+	Rules map[string]Requirement
+}
+
+// Match returns the Requirement for method, preferring an exact rule over
+// a wildcard one.
+func (p Policy) Match(method string) (Requirement, bool) {
+	if req, ok := p.Rules[method]; ok {
+		return req, true
+	}
+	for pattern, req := range p.Rules {
+		if !strings.HasSuffix(pattern, "*") {
+			continue
+		}
+		if strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+			return req, true
+		}
+	}
+	return Requirement{}, false
+}
+
+// PolicyProvider supplies the current Policy, allowing it to be reloaded
+// from a file or config service at runtime without restarting the
+// interceptor that consumes it.
+type PolicyProvider interface {
+	Policy() Policy
+}
+
+// StaticPolicyProvider is a PolicyProvider for a Policy that never
+// changes.
+type StaticPolicyProvider struct {
+	policy Policy
+}
+
+// NewStaticPolicyProvider wraps a fixed Policy as a PolicyProvider.
+func NewStaticPolicyProvider(policy Policy) StaticPolicyProvider {
+	return StaticPolicyProvider{policy: policy}
+}
+
+func (p StaticPolicyProvider) Policy() Policy {
+	return p.policy
+}
@@ -0,0 +1,156 @@
+package ban
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type strikeLog struct {
+	mu       sync.Mutex
+	times    []time.Time
+	lastSeen time.Time
+}
+
+// Hooks lets callers wire metrics/alerting into ban decisions.
+type Hooks struct {
+	// OnStrike is called every time a strike is recorded, with the total
+	// strikes still within the window after this one.
+	OnStrike func(key, reason string, strikes int)
+	// OnBan is called when a key is banned.
+	OnBan func(key string, duration time.Duration)
+}
+
+// Manager evaluates a Rule against reported strikes and bans offenders in
+// a Store once the threshold is met.
+type Manager struct {
+	store Store
+	rule  Rule
+	hooks Hooks
+
+	mu              sync.RWMutex
+	strikes         map[string]*strikeLog
+	cleanupInterval time.Duration
+	maxAge          time.Duration
+}
+
+// NewManager creates a Manager that bans keys in store according to rule,
+// periodically evicting strike history for keys that haven't struck out
+// in a while so m.strikes doesn't grow without bound.
+func NewManager(store Store, rule Rule) *Manager {
+	m := &Manager{
+		store:           store,
+		rule:            rule,
+		strikes:         make(map[string]*strikeLog),
+		cleanupInterval: 5 * time.Minute,
+		maxAge:          strikeMaxAge(rule),
+	}
+
+	go m.cleanupExpiredStrikes()
+
+	return m
+}
+
+func strikeMaxAge(rule Rule) time.Duration {
+	if rule.Window <= 0 {
+		return 15 * time.Minute
+	}
+	return 2 * rule.Window
+}
+
+// SetHooks installs metrics/alerting callbacks, replacing any previous ones.
+func (m *Manager) SetHooks(hooks Hooks) {
+	m.hooks = hooks
+}
+
+// Exempt reports whether method is exempt from ban enforcement, per the
+// Rule's ExemptMethods.
+func (m *Manager) Exempt(method string) bool {
+	return m.rule.isExempt(method)
+}
+
+// IsBanned reports whether key is currently banned.
+func (m *Manager) IsBanned(ctx context.Context, key string) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+	return m.store.IsBanned(ctx, key)
+}
+
+// Strike records an offense for key, identified by reason (e.g. a gRPC
+// code name or a caller-supplied description via Report), and bans key
+// once the Rule's threshold of strikes within its window is reached.
+func (m *Manager) Strike(ctx context.Context, key, reason string) error {
+	if key == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	log, ok := m.strikes[key]
+	if !ok {
+		log = &strikeLog{}
+		m.strikes[key] = log
+	}
+	m.mu.Unlock()
+
+	log.mu.Lock()
+	now := time.Now()
+	cutoff := now.Add(-m.rule.Window)
+	kept := log.times[:0]
+	for _, t := range log.times {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	log.times = append(kept, now)
+	log.lastSeen = now
+	strikes := len(log.times)
+	log.mu.Unlock()
+
+	if m.hooks.OnStrike != nil {
+		m.hooks.OnStrike(key, reason, strikes)
+	}
+
+	if strikes < m.rule.MaxStrikes {
+		return nil
+	}
+
+	if err := m.store.Ban(ctx, key, m.rule.BanDuration); err != nil {
+		return err
+	}
+	if m.hooks.OnBan != nil {
+		m.hooks.OnBan(key, m.rule.BanDuration)
+	}
+	return nil
+}
+
+func (m *Manager) cleanupExpiredStrikes() {
+	ticker := time.NewTicker(m.cleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		var toDelete []string
+
+		m.mu.RLock()
+		for key, log := range m.strikes {
+			log.mu.Lock()
+			lastSeen := log.lastSeen
+			log.mu.Unlock()
+			if now.Sub(lastSeen) > m.maxAge {
+				toDelete = append(toDelete, key)
+			}
+		}
+		m.mu.RUnlock()
+
+		if len(toDelete) == 0 {
+			continue
+		}
+
+		m.mu.Lock()
+		for _, key := range toDelete {
+			delete(m.strikes, key)
+		}
+		m.mu.Unlock()
+	}
+}
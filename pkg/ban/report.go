@@ -0,0 +1,28 @@
+package ban
+
+import "context"
+
+type requestKey struct{}
+
+type request struct {
+	manager *Manager
+	key     string
+}
+
+// WithManager attaches manager and the resolved caller key to ctx so that
+// Report can reach them from handler code, mirroring how AuthInterceptor
+// attaches claims to the context.
+func WithManager(ctx context.Context, manager *Manager, key string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{manager: manager, key: key})
+}
+
+// Report records a strike against the calling user/IP for reason. It is a
+// no-op if ctx was not produced by a chain that installs BanInterceptor
+// (e.g. in tests that don't wire one up).
+func Report(ctx context.Context, reason string) {
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok || req == nil || req.manager == nil {
+		return
+	}
+	_ = req.manager.Strike(ctx, req.key, reason)
+}
@@ -0,0 +1,26 @@
+package ban
+
+import "time"
+
+// Rule controls when accumulated strikes turn into a ban.
+type Rule struct {
+	// MaxStrikes is the number of strikes within Window that trigger a ban.
+	MaxStrikes int
+	// Window is the sliding window strikes are counted over.
+	Window time.Duration
+	// BanDuration is how long a triggered ban lasts.
+	BanDuration time.Duration
+	// ExemptMethods are full gRPC method names (e.g.
+	// "/grpc.health.v1.Health/Check") that never accrue strikes and are
+	// never blocked, regardless of ban state.
+	ExemptMethods []string
+}
+
+func (r Rule) isExempt(method string) bool {
+	for _, m := range r.ExemptMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
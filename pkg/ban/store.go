@@ -0,0 +1,95 @@
+package ban
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store persists bans for a key (typically "user:<id>" or "ip:<addr>")
+// until they expire. Implementations must be safe for concurrent use.
+type Store interface {
+	// Ban places key under ban for ttl, extending any existing ban if it
+	// expires sooner than the new one.
+	Ban(ctx context.Context, key string, ttl time.Duration) error
+	// IsBanned reports whether key is currently banned.
+	IsBanned(ctx context.Context, key string) (bool, error)
+}
+
+type memoryEntry struct {
+	until time.Time
+}
+
+// MemoryStore is the default in-process Store, with periodic cleanup of
+// expired entries, mirroring ratelimit.LocalLimiter's per-key map.
+type MemoryStore struct {
+	mu              sync.RWMutex
+	bans            map[string]memoryEntry
+	cleanupInterval time.Duration
+}
+
+// NewMemoryStore creates a MemoryStore that sweeps expired bans every
+// cleanupInterval (defaulting to 5 minutes).
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = 5 * time.Minute
+	}
+	s := &MemoryStore{
+		bans:            make(map[string]memoryEntry),
+		cleanupInterval: cleanupInterval,
+	}
+
+	go s.cleanupExpired()
+
+	return s
+}
+
+func (s *MemoryStore) Ban(_ context.Context, key string, ttl time.Duration) error {
+	until := time.Now().Add(ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.bans[key]; !ok || until.After(existing.until) {
+		s.bans[key] = memoryEntry{until: until}
+	}
+	return nil
+}
+
+func (s *MemoryStore) IsBanned(_ context.Context, key string) (bool, error) {
+	s.mu.RLock()
+	entry, ok := s.bans[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return false, nil
+	}
+	return time.Now().Before(entry.until), nil
+}
+
+func (s *MemoryStore) cleanupExpired() {
+	ticker := time.NewTicker(s.cleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		var expired []string
+
+		s.mu.RLock()
+		for key, entry := range s.bans {
+			if now.After(entry.until) {
+				expired = append(expired, key)
+			}
+		}
+		s.mu.RUnlock()
+
+		if len(expired) == 0 {
+			continue
+		}
+
+		s.mu.Lock()
+		for _, key := range expired {
+			delete(s.bans, key)
+		}
+		s.mu.Unlock()
+	}
+}
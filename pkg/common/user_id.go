@@ -8,6 +8,11 @@ import (
 
 const UserIDKey = "user_id"
 
+// GetUserID reads the client-supplied "user_id" metadata header. This is
+// NOT authenticated: any caller can set or omit it. Use it only where
+// that's acceptable (e.g. logging a hint before auth runs); for anything
+// security-sensitive (ban keys, per-user quotas) use AuthenticatedUserID
+// instead.
 func GetUserID(ctx context.Context) string {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -19,3 +24,20 @@ func GetUserID(ctx context.Context) string {
 	}
 	return values[0]
 }
+
+type authenticatedUserIDKey struct{}
+
+// WithAuthenticatedUserID returns a context carrying userID as the
+// JWT-verified identity of the caller. Called by AuthInterceptor once a
+// token's signature and claims have been checked; downstream code should
+// read it back with AuthenticatedUserID rather than trusting metadata.
+func WithAuthenticatedUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, authenticatedUserIDKey{}, userID)
+}
+
+// AuthenticatedUserID returns the JWT-verified user id set by
+// AuthInterceptor, or "" if the request was never authenticated.
+func AuthenticatedUserID(ctx context.Context) string {
+	userID, _ := ctx.Value(authenticatedUserIDKey{}).(string)
+	return userID
+}
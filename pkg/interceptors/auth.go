@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 
+	"github.com/chilly266futon/exchange-shared/pkg/authz"
+	"github.com/chilly266futon/exchange-shared/pkg/common"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -21,39 +23,95 @@ func AuthInterceptor(
 	jwtValidator JWTValidator,
 ) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			logger.Warn("missing metadata in request", zap.String("method", info.FullMethod))
-			return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
+		ctx, err := authenticate(ctx, logger, jwtValidator, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
+		return handler(ctx, req)
+	}
+}
 
-		tokens := md.Get("authorization")
-		if len(tokens) == 0 {
-			logger.Warn("missing authorization header", zap.String("method", info.FullMethod))
-			return nil, status.Errorf(codes.Unauthenticated, "missing authorization header")
+// StreamAuthInterceptor is the streaming equivalent of AuthInterceptor,
+// authenticating once when the stream opens.
+func StreamAuthInterceptor(
+	logger *zap.Logger,
+	jwtValidator JWTValidator,
+) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), logger, jwtValidator, info.FullMethod)
+		if err != nil {
+			return err
 		}
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
 
-		token := tokens[0]
-		if !strings.HasPrefix(token, "Bearer") {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid authorization format")
-		}
-		token = token[7:]
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
 
-		claims, err := jwtValidator.Validate(token)
-		if err != nil {
-			logger.Warn("invalid token", zap.String("method", info.FullMethod), zap.Error(err))
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
-		}
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
 
-		ctx = context.WithValue(ctx, "user_id", (*claims)["sub"].(string))
-		ctx = context.WithValue(ctx, "roles", (*claims)["roles"].([]string))
-		ctx = context.WithValue(ctx, "permissions", (*claims)["permissions"].([]string))
+func authenticate(ctx context.Context, logger *zap.Logger, jwtValidator JWTValidator, method string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		logger.Warn("missing metadata in request", zap.String("method", method))
+		return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
+	}
 
-		logger.Info("authenticated request",
-			zap.String("method", info.FullMethod),
-			zap.String("user_id", (*claims)["sub"].(string)),
-		)
+	tokens := md.Get("authorization")
+	if len(tokens) == 0 {
+		logger.Warn("missing authorization header", zap.String("method", method))
+		return nil, status.Errorf(codes.Unauthenticated, "missing authorization header")
+	}
 
-		return handler(ctx, req)
+	token := tokens[0]
+	if !strings.HasPrefix(token, "Bearer") {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid authorization format")
+	}
+	token = token[7:]
+
+	claims, err := jwtValidator.Validate(token)
+	if err != nil {
+		logger.Warn("invalid token", zap.String("method", method), zap.Error(err))
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+
+	userID, _ := (*claims)["sub"].(string)
+	ctx = common.WithAuthenticatedUserID(ctx, userID)
+	ctx = context.WithValue(ctx, authz.RolesContextKey, claimStringSlice(claims, "roles"))
+	ctx = context.WithValue(ctx, authz.PermissionsContextKey, claimStringSlice(claims, "permissions"))
+
+	logger.Info("authenticated request",
+		zap.String("method", method),
+		zap.String("user_id", userID),
+	)
+
+	return ctx, nil
+}
+
+// claimStringSlice safely reads a []string-shaped claim. JWT claims decode
+// from JSON as []interface{}, not []string, so a direct type assertion to
+// []string always fails (or panics, if asserted unchecked); this converts
+// element by element and drops anything that isn't a string.
+func claimStringSlice(claims *jwt.MapClaims, key string) []string {
+	raw, ok := (*claims)[key]
+	if !ok {
+		return nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
 	}
+	return out
 }
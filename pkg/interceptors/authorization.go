@@ -0,0 +1,36 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/chilly266futon/exchange-shared/pkg/authz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationInterceptor enforces an authz.Policy against the
+// roles/permissions AuthInterceptor placed in context, rejecting calls
+// that don't satisfy the method's Requirement with
+// codes.PermissionDenied. Methods with no matching rule in the policy are
+// allowed through unchecked. It must run after AuthInterceptor in the
+// chain.
+func AuthorizationInterceptor(policyProvider authz.PolicyProvider) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		requirement, ok := policyProvider.Policy().Match(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		if !authz.Check(ctx, requirement) {
+			return nil, status.Error(codes.PermissionDenied, "caller does not satisfy the method's authorization policy")
+		}
+
+		return handler(ctx, req)
+	}
+}
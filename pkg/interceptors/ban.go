@@ -0,0 +1,110 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+
+	"github.com/chilly266futon/exchange-shared/pkg/ban"
+	"github.com/chilly266futon/exchange-shared/pkg/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// BanInterceptor rejects calls from a banned user/IP with
+// codes.PermissionDenied before they reach handlers, and installs manager
+// into the context so handlers (and other interceptors) can call
+// ban.Report to register a strike. It also auto-strikes callers whose
+// requests fail with codes.ResourceExhausted or codes.Unauthenticated,
+// e.g. as surfaced by MethodRateLimiterInterceptor or AuthInterceptor.
+func BanInterceptor(manager *ban.Manager) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if manager.Exempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		key := banKey(ctx)
+		if banned, err := manager.IsBanned(ctx, key); err == nil && banned {
+			return nil, status.Error(codes.PermissionDenied, "caller is temporarily banned")
+		}
+
+		ctx = ban.WithManager(ctx, manager, key)
+		resp, err := handler(ctx, req)
+		autoStrike(ctx, manager, key, err)
+		return resp, err
+	}
+}
+
+// StreamBanInterceptor is the streaming equivalent of BanInterceptor. The
+// ban check happens once at stream open; a strike from the eventual
+// terminal error is still recorded once the stream ends.
+func StreamBanInterceptor(manager *ban.Manager) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if manager.Exempt(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx := ss.Context()
+		key := banKey(ctx)
+		if banned, err := manager.IsBanned(ctx, key); err == nil && banned {
+			return status.Error(codes.PermissionDenied, "caller is temporarily banned")
+		}
+
+		ctx = ban.WithManager(ctx, manager, key)
+		err := handler(srv, &banServerStream{ServerStream: ss, ctx: ctx})
+		autoStrike(ctx, manager, key, err)
+		return err
+	}
+}
+
+type banServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *banServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// banKey resolves the identity a ban applies to: the JWT-verified user
+// if any, falling back to the caller's bare peer IP (not host:port --
+// the ephemeral client port changes on every new TCP connection, so
+// keying on the full address would let an anonymous abusive caller
+// evade a ban just by reconnecting). It deliberately uses
+// common.AuthenticatedUserID, not common.GetUserID: the latter reads a
+// client-supplied metadata header, which would let a banned caller
+// evade the ban (by clearing the header) or frame another user (by
+// forging it).
+func banKey(ctx context.Context) string {
+	if userID := common.AuthenticatedUserID(ctx); userID != "" {
+		return "user:" + userID
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return ""
+}
+
+func autoStrike(ctx context.Context, manager *ban.Manager, key string, err error) {
+	if key == "" || err == nil {
+		return
+	}
+	switch status.Code(err) {
+	case codes.ResourceExhausted, codes.Unauthenticated:
+		_ = manager.Strike(ctx, key, status.Code(err).String())
+	}
+}
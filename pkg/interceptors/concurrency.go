@@ -0,0 +1,140 @@
+package interceptors
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// methodSemaphore caps concurrent in-flight calls to a method and tracks
+// how many callers are currently waiting to acquire it.
+type methodSemaphore struct {
+	slots    chan struct{}
+	maxQueue int32
+	queued   int32 // atomic
+}
+
+// ConcurrencyLimitInterceptor caps the number of concurrent in-flight RPCs
+// per method, separate from MethodRateLimiterInterceptor's req/sec
+// throttling. It protects against goroutine explosion from slow handlers
+// (e.g. order matching) where a requests-per-second limit alone isn't
+// enough. Methods without a configured limit are not throttled.
+type ConcurrencyLimitInterceptor struct {
+	mu      sync.RWMutex
+	methods map[string]*methodSemaphore
+
+	// AcquireTimeout bounds how long a call waits for a free slot once
+	// queued, in addition to respecting ctx.Done(). Zero means wait
+	// indefinitely (bounded only by the context).
+	AcquireTimeout time.Duration
+
+	// OnAcquire, OnQueued, OnDropped and OnReleased let callers wire
+	// metrics into the interceptor's lifecycle events. Any of them may be
+	// left nil.
+	OnAcquire  func(method string)
+	OnQueued   func(method string)
+	OnDropped  func(method string)
+	OnReleased func(method string)
+}
+
+// NewConcurrencyLimitInterceptor creates a ConcurrencyLimitInterceptor
+// with no method limits configured; call SetMethodLimit to add them.
+func NewConcurrencyLimitInterceptor(acquireTimeout time.Duration) *ConcurrencyLimitInterceptor {
+	return &ConcurrencyLimitInterceptor{
+		methods:        make(map[string]*methodSemaphore),
+		AcquireTimeout: acquireTimeout,
+	}
+}
+
+// SetMethodLimit caps method to maxConcurrent in-flight calls, with up to
+// maxQueue callers allowed to wait for a free slot before being rejected
+// immediately with codes.ResourceExhausted.
+func (c *ConcurrencyLimitInterceptor) SetMethodLimit(method string, maxConcurrent, maxQueue int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.methods[method] = &methodSemaphore{
+		slots:    make(chan struct{}, maxConcurrent),
+		maxQueue: int32(maxQueue),
+	}
+}
+
+// Interceptor returns the gRPC interceptor.
+func (c *ConcurrencyLimitInterceptor) Interceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		c.mu.RLock()
+		sem, ok := c.methods[info.FullMethod]
+		c.mu.RUnlock()
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		release, err := c.acquire(ctx, info.FullMethod, sem)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return handler(ctx, req)
+	}
+}
+
+func (c *ConcurrencyLimitInterceptor) acquire(ctx context.Context, method string, sem *methodSemaphore) (func(), error) {
+	release := func() {
+		<-sem.slots
+		if c.OnReleased != nil {
+			c.OnReleased(method)
+		}
+	}
+
+	// Take a free slot straight away if there is one; only calls over the
+	// concurrency cap are queued.
+	select {
+	case sem.slots <- struct{}{}:
+		if c.OnAcquire != nil {
+			c.OnAcquire(method)
+		}
+		return release, nil
+	default:
+	}
+
+	if atomic.AddInt32(&sem.queued, 1) > sem.maxQueue {
+		atomic.AddInt32(&sem.queued, -1)
+		if c.OnDropped != nil {
+			c.OnDropped(method)
+		}
+		return nil, status.Error(codes.ResourceExhausted, "too many queued requests for method")
+	}
+	if c.OnQueued != nil {
+		c.OnQueued(method)
+	}
+	defer atomic.AddInt32(&sem.queued, -1)
+
+	var timeoutCh <-chan time.Time
+	if c.AcquireTimeout > 0 {
+		timer := time.NewTimer(c.AcquireTimeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	select {
+	case sem.slots <- struct{}{}:
+		if c.OnAcquire != nil {
+			c.OnAcquire(method)
+		}
+		return release, nil
+	case <-ctx.Done():
+		return nil, status.Error(codes.ResourceExhausted, "concurrency limit: request canceled while queued")
+	case <-timeoutCh:
+		return nil, status.Error(codes.ResourceExhausted, "concurrency limit: timed out waiting for a free slot")
+	}
+}
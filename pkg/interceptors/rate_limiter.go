@@ -2,13 +2,15 @@ package interceptors
 
 import (
 	"context"
+	"strconv"
 	"sync"
-	"time"
 
 	"github.com/chilly266futon/exchange-shared/pkg/common"
+	"github.com/chilly266futon/exchange-shared/pkg/ratelimit"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -27,48 +29,82 @@ func RateLimiterInterceptor(limiter *rate.Limiter) grpc.UnaryServerInterceptor {
 	}
 }
 
+// StreamRateLimiterInterceptor is the streaming equivalent of
+// RateLimiterInterceptor, checked once when the stream opens.
+func StreamRateLimiterInterceptor(limiter *rate.Limiter) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if !limiter.Allow() {
+			return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(srv, ss)
+	}
+}
+
+// BackendFactory builds the Limiter backend used for a quota of the given
+// rate/burst. The default factory builds an in-process
+// ratelimit.LocalLimiter, which keeps that quota process-local; override
+// it via SetBackendFactory (e.g. to build ratelimit.RedisLimiter
+// instances) to make the global, per-method and per-user quotas all hold
+// across a fleet of replicas.
+type BackendFactory func(limit rate.Limit, burst int) ratelimit.Limiter
+
+func defaultBackendFactory(limit rate.Limit, burst int) ratelimit.Limiter {
+	return ratelimit.NewLocalLimiter(limit, burst)
+}
+
+// globalLimitKey is the Limiter key used for the global quota, which has
+// no natural per-call key of its own.
+const globalLimitKey = "global"
+
 // MethodRateLimiterInterceptor позволяет установить лимиты для конкретных методов
 type MethodRateLimiterInterceptor struct {
-	// per-method (меняется редко)
-	methodLimiters map[string]*rate.Limiter // per-method
-	defaultLimiter *rate.Limiter            // global
-	methodMu       sync.RWMutex             // защита map limiters
+	backendFactory BackendFactory
 
-	// per-user (hot)
-	perUserLimiters map[string]*userLimiterStruct // per-user
-	perUserMu       sync.RWMutex                  // защита map perUserLimiters
+	// global (cold config, hot check)
+	defaultLimit  rate.Limit
+	defaultBurst  int
+	globalMu      sync.Mutex
+	globalBackend ratelimit.Limiter
 
-	perUserRate     rate.Limit
-	perUserBurst    int
-	cleanupInterval time.Duration
-	maxAge          time.Duration
-}
+	// per-method (меняется редко)
+	methodMu       sync.RWMutex
+	methodBackends map[string]ratelimit.Limiter // per-method
 
-type userLimiterStruct struct {
-	limiter  *rate.Limiter
-	lastUsed time.Time
+	// per-user (hot)
+	perUserRate  rate.Limit
+	perUserBurst int
+	userMu       sync.Mutex
+	userBackend  ratelimit.Limiter
 }
 
 // NewMethodRateLimiterInterceptor создает новый interceptor с лимитами по методам
 func NewMethodRateLimiterInterceptor(defaultLimit rate.Limit, defaultBurst int) *MethodRateLimiterInterceptor {
-	m := &MethodRateLimiterInterceptor{
-		methodLimiters:  make(map[string]*rate.Limiter),
-		defaultLimiter:  rate.NewLimiter(defaultLimit, defaultBurst),
-		perUserLimiters: make(map[string]*userLimiterStruct),
-		cleanupInterval: 5 * time.Minute,
-		maxAge:          15 * time.Minute,
+	return &MethodRateLimiterInterceptor{
+		backendFactory: defaultBackendFactory,
+		defaultLimit:   defaultLimit,
+		defaultBurst:   defaultBurst,
+		methodBackends: make(map[string]ratelimit.Limiter),
 	}
+}
 
-	go m.cleanupOldUsers()
-
-	return m
+// SetBackendFactory overrides how Limiter backends are built for the
+// global, per-method and per-user quotas, e.g. to back all three with
+// Redis instead of the default in-process LocalLimiter. Call this before
+// serving traffic; it has no effect on backends already created.
+func (m *MethodRateLimiterInterceptor) SetBackendFactory(factory BackendFactory) {
+	m.backendFactory = factory
 }
 
 // SetMethodLimit устанавливает лимит для конкретного метода
 func (m *MethodRateLimiterInterceptor) SetMethodLimit(method string, limit rate.Limit, burst int) {
 	m.methodMu.Lock()
 	defer m.methodMu.Unlock()
-	m.methodLimiters[method] = rate.NewLimiter(limit, burst)
+	m.methodBackends[method] = m.backendFactory(limit, burst)
 }
 
 func (m *MethodRateLimiterInterceptor) SetPerUserLimit(limit rate.Limit, burst int) {
@@ -84,80 +120,118 @@ func (m *MethodRateLimiterInterceptor) Interceptor() grpc.UnaryServerInterceptor
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (any, error) {
-		// Global limit
-		if !m.defaultLimiter.Allow() {
-			return nil, status.Error(codes.ResourceExhausted, "global rate limit exceeded")
+		if err := m.checkLimits(ctx, info.FullMethod); err != nil {
+			return nil, err
 		}
+		return handler(ctx, req)
+	}
+}
 
-		// Per-method limit
-		m.methodMu.RLock()
-		limiter := m.defaultLimiter
-		if methodLimiter, ok := m.methodLimiters[info.FullMethod]; ok {
-			limiter = methodLimiter
+// StreamInterceptor is the streaming equivalent of Interceptor. The limit
+// is checked once when the stream opens, and again on every message
+// received from the client, since a long-lived stream (market data
+// feeds, order updates) could otherwise send unlimited messages once past
+// the initial check.
+func (m *MethodRateLimiterInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := ss.Context()
+		if err := m.checkLimits(ctx, info.FullMethod); err != nil {
+			return err
 		}
-		m.methodMu.RUnlock()
 
-		if !limiter.Allow() {
-			return nil, status.Error(codes.ResourceExhausted, "method rate limit exceeded")
-		}
+		return handler(srv, &rateLimitedServerStream{
+			ServerStream: ss,
+			check:        func() error { return m.checkLimits(ctx, info.FullMethod) },
+		})
+	}
+}
 
-		// Per-user limit
-		if m.perUserRate > 0 {
-			userID := common.GetUserID(ctx)
-			if userID == "" {
-				return nil, status.Error(codes.InvalidArgument, "user_id is required")
-			}
-
-			m.perUserMu.RLock()
-			user, ok := m.perUserLimiters[userID]
-			m.perUserMu.RUnlock()
-
-			if !ok {
-				m.perUserMu.Lock()
-				if user, ok = m.perUserLimiters[userID]; !ok {
-					user = &userLimiterStruct{
-						limiter:  rate.NewLimiter(m.perUserRate, m.perUserBurst),
-						lastUsed: time.Now(),
-					}
-					m.perUserMu.Unlock()
-				}
-			}
-
-			user.lastUsed = time.Now()
-
-			if !user.limiter.Allow() {
-				return nil, status.Error(codes.ResourceExhausted, "per-user rate limit exceeded")
-			}
-		}
+type rateLimitedServerStream struct {
+	grpc.ServerStream
+	check func() error
+}
 
-		return handler(ctx, req)
+func (s *rateLimitedServerStream) RecvMsg(m any) error {
+	if err := s.check(); err != nil {
+		return err
 	}
+	return s.ServerStream.RecvMsg(m)
 }
 
-func (m *MethodRateLimiterInterceptor) cleanupOldUsers() {
-	ticker := time.NewTicker(m.cleanupInterval)
-	defer ticker.Stop()
+// checkLimits runs the global, per-method and per-user checks shared by
+// Interceptor and StreamInterceptor. All three go through a Limiter
+// backend, so with SetBackendFactory pointed at Redis, quotas are
+// enforced consistently across a fleet rather than per-process.
+func (m *MethodRateLimiterInterceptor) checkLimits(ctx context.Context, method string) error {
+	// Global limit
+	if err := m.check(ctx, m.globalLimiterBackend(), globalLimitKey, "global rate limit exceeded"); err != nil {
+		return err
+	}
 
-	for range ticker.C {
-		now := time.Now()
-		var toDelete []string
+	// Per-method limit
+	m.methodMu.RLock()
+	methodBackend, ok := m.methodBackends[method]
+	m.methodMu.RUnlock()
 
-		m.perUserMu.RLock()
-		for userID, user := range m.perUserLimiters {
-			if now.Sub(user.lastUsed) > m.maxAge {
-				toDelete = append(toDelete, userID)
-			}
+	if ok {
+		if err := m.check(ctx, methodBackend, method, "method rate limit exceeded"); err != nil {
+			return err
 		}
-		m.perUserMu.RUnlock()
+	}
 
-		if len(toDelete) > 0 {
-			continue
+	// Per-user limit
+	if m.perUserRate > 0 {
+		userID := common.AuthenticatedUserID(ctx)
+		if userID == "" {
+			return status.Error(codes.InvalidArgument, "user_id is required")
 		}
 
-		m.perUserMu.Lock()
-		for _, userID := range toDelete {
-			delete(m.perUserLimiters, userID)
+		if err := m.check(ctx, m.userLimiterBackend(), userID, "per-user rate limit exceeded"); err != nil {
+			return err
 		}
-		m.perUserMu.Unlock()
 	}
+
+	return nil
+}
+
+// check runs backend.Allow for key, translating the result into the
+// gRPC errors and retry-after-ms trailer callers expect.
+func (m *MethodRateLimiterInterceptor) check(ctx context.Context, backend ratelimit.Limiter, key, exceededMsg string) error {
+	allowed, retryAfter, err := backend.Allow(ctx, key)
+	if err != nil {
+		return status.Error(codes.Internal, "rate limiter backend unavailable")
+	}
+	if !allowed {
+		grpc.SetTrailer(ctx, metadata.Pairs("retry-after-ms", strconv.FormatInt(retryAfter.Milliseconds(), 10)))
+		return status.Error(codes.ResourceExhausted, exceededMsg)
+	}
+	return nil
+}
+
+// globalLimiterBackend returns the Limiter backend for the global quota,
+// lazily building it with the current backendFactory so SetBackendFactory
+// can be called after construction but before traffic starts.
+func (m *MethodRateLimiterInterceptor) globalLimiterBackend() ratelimit.Limiter {
+	m.globalMu.Lock()
+	defer m.globalMu.Unlock()
+	if m.globalBackend == nil {
+		m.globalBackend = m.backendFactory(m.defaultLimit, m.defaultBurst)
+	}
+	return m.globalBackend
+}
+
+// userLimiterBackend returns the Limiter backend for per-user quotas,
+// lazily defaulting to backendFactory sized from SetPerUserLimit.
+func (m *MethodRateLimiterInterceptor) userLimiterBackend() ratelimit.Limiter {
+	m.userMu.Lock()
+	defer m.userMu.Unlock()
+	if m.userBackend == nil {
+		m.userBackend = m.backendFactory(m.perUserRate, m.perUserBurst)
+	}
+	return m.userBackend
 }
@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+func (s *fakeServerStream) RecvMsg(any) error        { return nil }
+
+func TestMethodRateLimiterStreamInterceptorEnforcesPerMessage(t *testing.T) {
+	const method = "/exchange.OrderService/StreamOrders"
+
+	m := NewMethodRateLimiterInterceptor(rate.Limit(1000), 1000)
+	m.SetMethodLimit(method, rate.Limit(0), 2) // burst of 2, no refill
+
+	interceptor := m.StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: method}
+
+	received := 0
+	handler := func(_ any, ss grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			if err := ss.RecvMsg(struct{}{}); err != nil {
+				return err
+			}
+			received++
+		}
+		return nil
+	}
+
+	err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, handler)
+
+	// One token for the open-of-stream check, one for the first RecvMsg;
+	// the second RecvMsg should find the bucket empty.
+	if received != 1 {
+		t.Fatalf("expected exactly 1 message to pass before the per-message check throttles the stream, got %d", received)
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected codes.ResourceExhausted once the method limit is exhausted, got %v", err)
+	}
+}
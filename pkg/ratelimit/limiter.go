@@ -0,0 +1,15 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter decides whether a call identified by key may proceed under a
+// token-bucket quota. Implementations must be safe for concurrent use.
+type Limiter interface {
+	// Allow reports whether a call against key may proceed. When not
+	// allowed, retryAfter is a hint for how long the caller should wait
+	// before retrying.
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
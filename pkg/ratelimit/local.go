@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type localEntry struct {
+	limiter *rate.Limiter
+
+	mu       sync.Mutex
+	lastUsed time.Time
+}
+
+// LocalLimiter is an in-process token-bucket Limiter keyed by an arbitrary
+// string (e.g. "method:"+FullMethod or "user:"+userID). It backs
+// MethodRateLimiterInterceptor by default, and is also used as the
+// fallback when a remote backend such as RedisLimiter is unreachable.
+type LocalLimiter struct {
+	rate  rate.Limit
+	burst int
+
+	mu      sync.RWMutex
+	entries map[string]*localEntry
+
+	cleanupInterval time.Duration
+	maxAge          time.Duration
+}
+
+// NewLocalLimiter creates a LocalLimiter granting burst tokens refilled at
+// limit per second to each distinct key.
+func NewLocalLimiter(limit rate.Limit, burst int) *LocalLimiter {
+	l := &LocalLimiter{
+		rate:            limit,
+		burst:           burst,
+		entries:         make(map[string]*localEntry),
+		cleanupInterval: 5 * time.Minute,
+		maxAge:          15 * time.Minute,
+	}
+
+	go l.cleanupOldEntries()
+
+	return l
+}
+
+func (l *LocalLimiter) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	l.mu.RLock()
+	entry, ok := l.entries[key]
+	l.mu.RUnlock()
+
+	if !ok {
+		l.mu.Lock()
+		if entry, ok = l.entries[key]; !ok {
+			entry = &localEntry{
+				limiter: rate.NewLimiter(l.rate, l.burst),
+			}
+			l.entries[key] = entry
+		}
+		l.mu.Unlock()
+	}
+
+	entry.mu.Lock()
+	entry.lastUsed = time.Now()
+	entry.mu.Unlock()
+
+	if !entry.limiter.Allow() {
+		return false, l.retryAfter(), nil
+	}
+	return true, 0, nil
+}
+
+// retryAfter estimates how long a caller should wait before its bucket
+// refills a token. l.rate == 0 is a legitimate "burst only, no refill"
+// configuration (e.g. a hard per-stream message cap); dividing by it
+// would produce +Inf, which time.Duration silently truncates into a
+// huge nonsense value, so report the zero-rate case as "no ETA" instead.
+func (l *LocalLimiter) retryAfter() time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / float64(l.rate))
+}
+
+func (l *LocalLimiter) cleanupOldEntries() {
+	ticker := time.NewTicker(l.cleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		l.sweep(time.Now())
+	}
+}
+
+// sweep deletes entries not used since before now-maxAge. Split out of
+// cleanupOldEntries so tests can drive a sweep deterministically instead
+// of waiting on the ticker.
+func (l *LocalLimiter) sweep(now time.Time) {
+	var toDelete []string
+
+	l.mu.RLock()
+	for key, entry := range l.entries {
+		entry.mu.Lock()
+		lastUsed := entry.lastUsed
+		entry.mu.Unlock()
+		if now.Sub(lastUsed) > l.maxAge {
+			toDelete = append(toDelete, key)
+		}
+	}
+	l.mu.RUnlock()
+
+	if len(toDelete) == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	for _, key := range toDelete {
+		delete(l.entries, key)
+	}
+	l.mu.Unlock()
+}
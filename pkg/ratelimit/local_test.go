@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestLocalLimiterConcurrentCreate(t *testing.T) {
+	l := NewLocalLimiter(rate.Limit(1000), 1000)
+
+	const goroutines = 50
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			if _, _, err := l.Allow(context.Background(), "shared-key"); err != nil {
+				t.Errorf("Allow returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	l.mu.RLock()
+	entries := len(l.entries)
+	l.mu.RUnlock()
+
+	if entries != 1 {
+		t.Fatalf("expected exactly one entry for the shared key after concurrent create, got %d", entries)
+	}
+}
+
+func TestLocalLimiterTTLEviction(t *testing.T) {
+	l := NewLocalLimiter(rate.Limit(1), 1)
+
+	if _, _, err := l.Allow(context.Background(), "stale-key"); err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+
+	l.mu.Lock()
+	l.entries["stale-key"].lastUsed = time.Now().Add(-l.maxAge - time.Second)
+	l.mu.Unlock()
+
+	l.sweep(time.Now())
+
+	l.mu.RLock()
+	_, ok := l.entries["stale-key"]
+	l.mu.RUnlock()
+
+	if ok {
+		t.Fatal("expected entry past maxAge to be evicted by sweep")
+	}
+}